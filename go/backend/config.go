@@ -0,0 +1,42 @@
+package backend
+
+import "fmt"
+
+// Config carries the backend-specific settings read from the
+// terraform.backend object in shared.json. Only the fields relevant to
+// the selected Type need to be populated; the rest are ignored.
+type Config struct {
+	Type string `json:"type"`
+
+	// s3dynamodb
+	Bucket                          string `json:"bucket"`
+	DynamoDBTable                   string `json:"dynamodbTable"`
+	Region                          string `json:"region"`
+	KmsKeyId                        string `json:"kmsKeyId"`
+	NoncurrentVersionExpirationDays int    `json:"noncurrentVersionExpirationDays"`
+
+	// gcs
+	ProjectID string `json:"projectId"`
+	GCSBucket string `json:"gcsBucket"`
+	GCSPrefix string `json:"gcsPrefix"`
+
+	// tfe
+	Organization string `json:"organization"`
+	Hostname     string `json:"hostname"`
+}
+
+// New selects and constructs the Provider named by cfg.Type, defaulting to
+// "s3dynamodb" so existing shared.json files that predate this field keep
+// working unchanged.
+func New(cfg Config) (Provider, error) {
+	switch cfg.Type {
+	case "", "s3dynamodb":
+		return newS3DynamoDB(cfg), nil
+	case "gcs":
+		return newGCS(cfg), nil
+	case "tfe":
+		return newTFE(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown terraform.backend.type %q", cfg.Type)
+	}
+}
@@ -0,0 +1,38 @@
+// Package backend abstracts the Terraform state backend a stack deploys
+// against, so the pipeline isn't hard-wired to AWS S3 + DynamoDB and can
+// also target GCS or Terraform Cloud/Enterprise from the same binary.
+package backend
+
+import "context"
+
+// Provider is implemented once per backend technology. The pipeline loads
+// the Provider named by terraform.backend.type in shared.json and drives
+// all backend state (creation, per-stack config, lock inspection) through
+// this interface instead of calling backend-specific SDKs directly.
+type Provider interface {
+	// EnsureBackend creates or verifies the backend's storage (and lock
+	// table, where applicable) exists and is hardened for production use.
+	EnsureBackend(ctx context.Context) error
+
+	// RenderCDKTFBackendConfig returns the `backend` block CDKTF should
+	// synth into the named stack's Terraform JSON.
+	RenderCDKTFBackendConfig(stack string) (map[string]any, error)
+
+	// ListLocks returns any state locks currently held against this
+	// backend, across all stacks.
+	ListLocks(ctx context.Context) ([]LockInfo, error)
+
+	// ForceUnlock releases the lock with the given ID, mirroring the
+	// semantics of `terraform force-unlock <id>`.
+	ForceUnlock(ctx context.Context, id string) error
+}
+
+// LockInfo mirrors the Info blob Terraform writes alongside a state lock,
+// trimmed to the fields the pipeline needs to display and act on.
+type LockInfo struct {
+	ID        string `json:"ID"`
+	Operation string `json:"Operation"`
+	Who       string `json:"Who"`
+	Created   string `json:"Created"`
+	Path      string `json:"Path"`
+}
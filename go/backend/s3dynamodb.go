@@ -0,0 +1,293 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// s3DynamoDBProvider is the original backend: an S3 bucket for state,
+// hardened per the bucket-hardening requirements, with a DynamoDB table
+// for state locking.
+type s3DynamoDBProvider struct {
+	cfg Config
+}
+
+func newS3DynamoDB(cfg Config) Provider {
+	return &s3DynamoDBProvider{cfg: cfg}
+}
+
+func (p *s3DynamoDBProvider) clients(ctx context.Context) (*s3.Client, *dynamodb.Client, error) {
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(p.cfg.Region))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to load AWS SDK config: %w", err)
+	}
+	return s3.NewFromConfig(awsCfg), dynamodb.NewFromConfig(awsCfg), nil
+}
+
+func (p *s3DynamoDBProvider) EnsureBackend(ctx context.Context) error {
+	s3Client, dynamo, err := p.clients(ctx)
+	if err != nil {
+		return err
+	}
+
+	bucket := p.cfg.Bucket
+	_, err = s3Client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		if p.cfg.Region == "us-east-1" {
+			_, err = s3Client.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: aws.String(bucket)})
+		} else {
+			_, err = s3Client.CreateBucket(ctx, &s3.CreateBucketInput{
+				Bucket: aws.String(bucket),
+				CreateBucketConfiguration: &s3Types.CreateBucketConfiguration{
+					LocationConstraint: s3Types.BucketLocationConstraint(p.cfg.Region),
+				},
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create bucket: %w", err)
+		}
+	}
+
+	if _, err := s3Client.PutBucketVersioning(ctx, &s3.PutBucketVersioningInput{
+		Bucket: aws.String(bucket),
+		VersioningConfiguration: &s3Types.VersioningConfiguration{
+			Status: s3Types.BucketVersioningStatusEnabled,
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to enable versioning: %w", err)
+	}
+
+	if _, err := s3Client.PutPublicAccessBlock(ctx, &s3.PutPublicAccessBlockInput{
+		Bucket: aws.String(bucket),
+		PublicAccessBlockConfiguration: &s3Types.PublicAccessBlockConfiguration{
+			BlockPublicAcls:       aws.Bool(true),
+			IgnorePublicAcls:      aws.Bool(true),
+			BlockPublicPolicy:     aws.Bool(true),
+			RestrictPublicBuckets: aws.Bool(true),
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to block public access: %w", err)
+	}
+
+	sseAlgorithm := s3Types.ServerSideEncryptionAes256
+	var kmsKeyId *string
+	if p.cfg.KmsKeyId != "" {
+		sseAlgorithm = s3Types.ServerSideEncryptionAwsKms
+		kmsKeyId = aws.String(p.cfg.KmsKeyId)
+	}
+	if _, err := s3Client.PutBucketEncryption(ctx, &s3.PutBucketEncryptionInput{
+		Bucket: aws.String(bucket),
+		ServerSideEncryptionConfiguration: &s3Types.ServerSideEncryptionConfiguration{
+			Rules: []s3Types.ServerSideEncryptionRule{
+				{
+					ApplyServerSideEncryptionByDefault: &s3Types.ServerSideEncryptionByDefault{
+						SSEAlgorithm:   sseAlgorithm,
+						KMSMasterKeyID: kmsKeyId,
+					},
+					BucketKeyEnabled: aws.Bool(true),
+				},
+			},
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to enable default encryption: %w", err)
+	}
+
+	if _, err := s3Client.PutBucketPolicy(ctx, &s3.PutBucketPolicyInput{
+		Bucket: aws.String(bucket),
+		Policy: aws.String(tlsOnlyBucketPolicy(bucket)),
+	}); err != nil {
+		return fmt.Errorf("failed to apply TLS-only bucket policy: %w", err)
+	}
+
+	expirationDays := p.cfg.NoncurrentVersionExpirationDays
+	if expirationDays <= 0 {
+		expirationDays = 90
+	}
+	if _, err := s3Client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+		Bucket: aws.String(bucket),
+		LifecycleConfiguration: &s3Types.BucketLifecycleConfiguration{
+			Rules: []s3Types.LifecycleRule{
+				{
+					ID:     aws.String("expire-noncurrent-versions"),
+					Status: s3Types.ExpirationStatusEnabled,
+					Filter: &s3Types.LifecycleRuleFilter{Prefix: aws.String("")},
+					NoncurrentVersionExpiration: &s3Types.NoncurrentVersionExpiration{
+						NoncurrentDays: aws.Int32(int32(expirationDays)),
+					},
+					AbortIncompleteMultipartUpload: &s3Types.AbortIncompleteMultipartUpload{
+						DaysAfterInitiation: aws.Int32(7),
+					},
+				},
+			},
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to configure lifecycle rules: %w", err)
+	}
+
+	table := p.cfg.DynamoDBTable
+	if _, err := dynamo.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)}); err != nil {
+		_, err = dynamo.CreateTable(ctx, &dynamodb.CreateTableInput{
+			TableName: aws.String(table),
+			AttributeDefinitions: []ddbTypes.AttributeDefinition{
+				{AttributeName: aws.String("LockID"), AttributeType: ddbTypes.ScalarAttributeTypeS},
+			},
+			KeySchema: []ddbTypes.KeySchemaElement{
+				{AttributeName: aws.String("LockID"), KeyType: ddbTypes.KeyTypeHash},
+			},
+			BillingMode: ddbTypes.BillingModePayPerRequest,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create lock table: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// tlsOnlyBucketPolicy returns a bucket policy document that denies any
+// request made without TLS, matching the aws:SecureTransport condition
+// used by the upstream S3 backend resource's hardening examples.
+func tlsOnlyBucketPolicy(bucket string) string {
+	policy := map[string]interface{}{
+		"Version": "2012-10-17",
+		"Statement": []map[string]interface{}{
+			{
+				"Sid":       "DenyInsecureTransport",
+				"Effect":    "Deny",
+				"Principal": "*",
+				"Action":    "s3:*",
+				"Resource": []string{
+					"arn:aws:s3:::" + bucket,
+					"arn:aws:s3:::" + bucket + "/*",
+				},
+				"Condition": map[string]interface{}{
+					"Bool": map[string]interface{}{
+						"aws:SecureTransport": "false",
+					},
+				},
+			},
+		},
+	}
+	data, _ := json.Marshal(policy)
+	return string(data)
+}
+
+func (p *s3DynamoDBProvider) RenderCDKTFBackendConfig(stack string) (map[string]any, error) {
+	return map[string]any{
+		"s3": map[string]any{
+			"bucket":         p.cfg.Bucket,
+			"key":            stateKeyForStack(stack),
+			"region":         p.cfg.Region,
+			"dynamodb_table": p.cfg.DynamoDBTable,
+			"encrypt":        true,
+		},
+	}, nil
+}
+
+// stateKeyForStack isolates each stack's state under its own key instead
+// of sharing one key across every stack, mirroring the named-state /
+// workspaces pattern: "escambo-dns" (the only global stack) gets
+// "global/dns.tfstate", and "escambo-<env>-<suffix>" gets
+// "env/<env>/<suffix>.tfstate".
+func stateKeyForStack(stack string) string {
+	if stack == "escambo-dns" {
+		return "global/dns.tfstate"
+	}
+	trimmed := strings.TrimPrefix(stack, "escambo-")
+	if parts := strings.SplitN(trimmed, "-", 2); len(parts) == 2 {
+		return fmt.Sprintf("env/%s/%s.tfstate", parts[0], parts[1])
+	}
+	return trimmed + ".tfstate"
+}
+
+// lockIDEntry is the shape of each item in the lock table: LockID is the
+// Terraform S3 backend's "<bucket>/<key>-md5" key, Info is a JSON blob
+// describing who holds the lock and why.
+type lockIDEntry struct {
+	LockID string `dynamodbav:"LockID"`
+	Info   string `dynamodbav:"Info"`
+}
+
+func (p *s3DynamoDBProvider) ListLocks(ctx context.Context) ([]LockInfo, error) {
+	_, dynamo, err := p.clients(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := dynamo.Scan(ctx, &dynamodb.ScanInput{
+		TableName: aws.String(p.cfg.DynamoDBTable),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan lock table: %w", err)
+	}
+
+	locks := make([]LockInfo, 0, len(out.Items))
+	for _, item := range out.Items {
+		infoAttr, ok := item["Info"]
+		if !ok {
+			continue
+		}
+		infoVal, ok := infoAttr.(*ddbTypes.AttributeValueMemberS)
+		if !ok {
+			continue
+		}
+		var info LockInfo
+		if err := json.Unmarshal([]byte(infoVal.Value), &info); err != nil {
+			continue
+		}
+		locks = append(locks, info)
+	}
+	return locks, nil
+}
+
+func (p *s3DynamoDBProvider) ForceUnlock(ctx context.Context, id string) error {
+	_, dynamo, err := p.clients(ctx)
+	if err != nil {
+		return err
+	}
+
+	out, err := dynamo.Scan(ctx, &dynamodb.ScanInput{
+		TableName: aws.String(p.cfg.DynamoDBTable),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan lock table: %w", err)
+	}
+
+	for _, item := range out.Items {
+		lockIDAttr, ok := item["LockID"].(*ddbTypes.AttributeValueMemberS)
+		if !ok {
+			continue
+		}
+		infoAttr, ok := item["Info"].(*ddbTypes.AttributeValueMemberS)
+		if !ok {
+			continue
+		}
+		var info LockInfo
+		if err := json.Unmarshal([]byte(infoAttr.Value), &info); err != nil {
+			continue
+		}
+		if info.ID != id {
+			continue
+		}
+		_, err := dynamo.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName: aws.String(p.cfg.DynamoDBTable),
+			Key: map[string]ddbTypes.AttributeValue{
+				"LockID": &ddbTypes.AttributeValueMemberS{Value: lockIDAttr.Value},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete lock %s: %w", lockIDAttr.Value, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("no lock found with ID %s", id)
+}
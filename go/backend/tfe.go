@@ -0,0 +1,101 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	tfe "github.com/hashicorp/go-tfe"
+)
+
+// tfeProvider targets a Terraform Cloud/Enterprise workspace. State
+// storage and locking are both managed by TFE itself, so EnsureBackend
+// only checks that the organization answers by reading its "default"
+// workspace; the per-stack workspaces named in RenderCDKTFBackendConfig
+// are neither checked nor created.
+type tfeProvider struct {
+	cfg    Config
+	client *tfe.Client
+}
+
+func newTFE(cfg Config) Provider {
+	return &tfeProvider{cfg: cfg}
+}
+
+func (p *tfeProvider) client_(ctx context.Context) (*tfe.Client, error) {
+	if p.client != nil {
+		return p.client, nil
+	}
+	client, err := tfe.NewClient(&tfe.Config{
+		Address: "https://" + p.cfg.Hostname,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create TFE client: %w", err)
+	}
+	p.client = client
+	return client, nil
+}
+
+func (p *tfeProvider) EnsureBackend(ctx context.Context) error {
+	client, err := p.client_(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = client.Workspaces.Read(ctx, p.cfg.Organization, "default")
+	if err != nil {
+		return fmt.Errorf("failed to verify TFE organization %q is reachable: %w", p.cfg.Organization, err)
+	}
+	return nil
+}
+
+func (p *tfeProvider) RenderCDKTFBackendConfig(stack string) (map[string]any, error) {
+	hostname := p.cfg.Hostname
+	if hostname == "" {
+		hostname = "app.terraform.io"
+	}
+	return map[string]any{
+		"remote": map[string]any{
+			"hostname":     hostname,
+			"organization": p.cfg.Organization,
+			"workspaces": map[string]any{
+				"name": stack,
+			},
+		},
+	}, nil
+}
+
+func (p *tfeProvider) ListLocks(ctx context.Context) ([]LockInfo, error) {
+	client, err := p.client_(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaces, err := client.Workspaces.List(ctx, p.cfg.Organization, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list TFE workspaces: %w", err)
+	}
+
+	var locks []LockInfo
+	for _, ws := range workspaces.Items {
+		if !ws.Locked {
+			continue
+		}
+		locks = append(locks, LockInfo{
+			ID:        ws.ID,
+			Operation: "tfe-workspace-lock",
+			Path:      ws.Name,
+		})
+	}
+	return locks, nil
+}
+
+func (p *tfeProvider) ForceUnlock(ctx context.Context, id string) error {
+	client, err := p.client_(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = client.Workspaces.ForceUnlock(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to unlock TFE workspace %s: %w", id, err)
+	}
+	return nil
+}
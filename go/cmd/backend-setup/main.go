@@ -0,0 +1,65 @@
+// Command backend-setup provisions and hardens the Terraform state
+// backend declared in config/shared/shared.json without running the rest
+// of the deploy pipeline. It's a thin wrapper over backend.Provider, for
+// provisioning the backend ahead of a first deploy or verifying it
+// out-of-band.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+
+	"escambo-pipeline/backend"
+)
+
+type sharedConfig struct {
+	Aws struct {
+		Region string `json:"region"`
+	} `json:"aws"`
+	Terraform struct {
+		Backend backend.Config `json:"backend"`
+	} `json:"terraform"`
+}
+
+func loadSharedConfig() (sharedConfig, error) {
+	var cfg sharedConfig
+	sharedFile := filepath.Join("config", "shared", "shared.json")
+	data, err := os.ReadFile(sharedFile)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, err
+	}
+	cfg.Terraform.Backend.Region = cfg.Aws.Region
+	return cfg, nil
+}
+
+func main() {
+	ctx := context.Background()
+	success := color.New(color.FgGreen).SprintFunc()
+	warn := color.New(color.FgYellow).SprintFunc()
+	fail := color.New(color.FgRed).SprintFunc()
+
+	cfg, err := loadSharedConfig()
+	if err != nil {
+		log.Fatalf("Failed to read shared config: %v", err)
+	}
+
+	provider, err := backend.New(cfg.Terraform.Backend)
+	if err != nil {
+		log.Fatalf("Failed to load backend provider: %v", err)
+	}
+
+	fmt.Println(warn("==> Ensuring Terraform state backend is provisioned"))
+	if err := provider.EnsureBackend(ctx); err != nil {
+		log.Fatalf("%s Failed to ensure backend: %v", fail("✖"), err)
+	}
+	fmt.Println(success("✔ Terraform state backend ready"))
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"escambo-pipeline/backend"
+)
+
+// StackDef is one node in the deploy DAG: a stack name, the env it
+// belongs to ("global" for escambo-dns), and the stacks it must wait on.
+type StackDef struct {
+	Name      string
+	Env       string
+	DependsOn []string
+}
+
+// perEnvSuffixDeps declares, for each per-environment stack suffix, which
+// other suffixes in the SAME environment it depends on. Every
+// per-environment stack additionally depends on the global escambo-dns
+// stack; that edge is added in buildStackDefs rather than repeated here.
+var perEnvSuffixDeps = map[string][]string{
+	"ecr":                       nil,
+	"customer-react-web-client": {"ecr"},
+	"provider-react-web-client": {"ecr"},
+	"core-java-service":         {"ecr"},
+	"widgets-java-service":      {"ecr"},
+}
+
+var perEnvSuffixOrder = []string{
+	"ecr",
+	"customer-react-web-client",
+	"provider-react-web-client",
+	"core-java-service",
+	"widgets-java-service",
+}
+
+// buildStackDefs expands perEnvSuffixDeps into the full stack graph for
+// the given environments, plus the one global "escambo-dns" stack every
+// per-environment stack depends on.
+func buildStackDefs(envs []string) []StackDef {
+	defs := []StackDef{{Name: "escambo-dns", Env: "global"}}
+
+	for _, env := range envs {
+		for _, suffix := range perEnvSuffixOrder {
+			dependsOn := []string{"escambo-dns"}
+			for _, dep := range perEnvSuffixDeps[suffix] {
+				dependsOn = append(dependsOn, "escambo-"+env+"-"+dep)
+			}
+			defs = append(defs, StackDef{
+				Name:      "escambo-" + env + "-" + suffix,
+				Env:       env,
+				DependsOn: dependsOn,
+			})
+		}
+	}
+	return defs
+}
+
+// deployStacksDAG deploys stacks concurrently through a worker pool sized
+// by parallelism, starting each stack only once all of its DependsOn have
+// finished successfully. A failed (or skipped) stack propagates a skip to
+// everything that depends on it, but already-running siblings are left to
+// finish. Returns false if any stack failed or was skipped as a result.
+func deployStacksDAG(ctx context.Context, defs []StackDef, provider backend.Provider, approve bool, parallelism int, lockTTL int, success, warn, fail func(a ...interface{}) string) bool {
+	doneCh := make(map[string]chan struct{}, len(defs))
+	for _, d := range defs {
+		doneCh[d.Name] = make(chan struct{})
+	}
+
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	var anyFailed atomic.Bool
+	var skipped sync.Map // stack name -> true if it failed or was skipped
+
+	for _, d := range defs {
+		d := d
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(doneCh[d.Name])
+
+			for _, dep := range d.DependsOn {
+				<-doneCh[dep]
+				if _, wasSkipped := skipped.Load(dep); wasSkipped {
+					fmt.Println(warn(fmt.Sprintf("[SKIP] %s: dependency %s did not succeed", d.Name, dep)))
+					skipped.Store(d.Name, true)
+					anyFailed.Store(true)
+					return
+				}
+			}
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			// Re-check for stale locks here, not just once before the DAG
+			// starts: a sibling stack that crashed mid-deploy while this
+			// one was waiting on its dependencies could have left a lock
+			// behind since the upfront check; it is refused here once it is
+			// older than lockTTL minutes. Errors here must only
+			// fail this stack (and its dependents), never the whole
+			// process, or one stack's stale lock would kill every other
+			// stack's in-flight `cdktf deploy`.
+			if err := preflightLocks(ctx, provider, lockTTL, success, warn, fail); err != nil {
+				fmt.Println(fail(fmt.Sprintf("[FAILED] %s: %v", d.Name, err)))
+				skipped.Store(d.Name, true)
+				anyFailed.Store(true)
+				return
+			}
+
+			if err := writeBackendConfig(provider, d.Name); err != nil {
+				fmt.Println(fail(fmt.Sprintf("[FAILED] %s: %v", d.Name, err)))
+				skipped.Store(d.Name, true)
+				anyFailed.Store(true)
+				return
+			}
+			logw := newPrefixedWriter(d.Name)
+			if !deployStack(d.Name, d.Env, approve, logw, success, warn, fail) {
+				skipped.Store(d.Name, true)
+				anyFailed.Store(true)
+			}
+		}()
+	}
+
+	wg.Wait()
+	return !anyFailed.Load()
+}
+
+// prefixedWriter tags every line a worker writes with "[stack-name] " and
+// serializes writes across workers under a shared mutex, so concurrent
+// `cdktf deploy` output doesn't interleave mid-line.
+type prefixedWriter struct {
+	prefix string
+}
+
+var prefixedWriterMu sync.Mutex
+
+func newPrefixedWriter(name string) io.Writer {
+	return &prefixedWriter{prefix: "[" + name + "] "}
+}
+
+func (w *prefixedWriter) Write(p []byte) (int, error) {
+	prefixedWriterMu.Lock()
+	defer prefixedWriterMu.Unlock()
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		fmt.Println(w.prefix + line)
+	}
+	return len(p), nil
+}
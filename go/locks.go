@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+
+	"escambo-pipeline/backend"
+)
+
+// runLocksCommand implements `escambo-pipeline locks list|unlock <id>|wait`,
+// a preflight tool for inspecting and releasing state locks before they're
+// left behind by a crashed `cdktf deploy`.
+func runLocksCommand(ctx context.Context, args []string, provider backend.Provider, lockTTL int) {
+	success := color.New(color.FgGreen).SprintFunc()
+	warn := color.New(color.FgYellow).SprintFunc()
+	fail := color.New(color.FgRed).SprintFunc()
+
+	if len(args) == 0 {
+		log.Fatalf("%s Usage: escambo-pipeline locks list|unlock <id>|wait", fail("✖"))
+	}
+
+	switch args[0] {
+	case "list":
+		locks, err := provider.ListLocks(ctx)
+		if err != nil {
+			log.Fatalf("%s Failed to list state locks: %v", fail("✖"), err)
+		}
+		if len(locks) == 0 {
+			fmt.Println(success("✔ No state locks held"))
+			return
+		}
+		printLocksTable(locks, lockTTL)
+
+	case "unlock":
+		if len(args) != 2 {
+			log.Fatalf("%s Usage: escambo-pipeline locks unlock <id>", fail("✖"))
+		}
+		if err := provider.ForceUnlock(ctx, args[1]); err != nil {
+			log.Fatalf("%s Failed to force-unlock %s: %v", fail("✖"), args[1], err)
+		}
+		fmt.Println(success("✔ Lock released: " + args[1]))
+
+	case "wait":
+		fmt.Println(warn("==> Waiting for state locks to clear..."))
+		deadline := time.Now().Add(10 * time.Minute)
+		for {
+			locks, err := provider.ListLocks(ctx)
+			if err != nil {
+				log.Fatalf("%s Failed to list state locks: %v", fail("✖"), err)
+			}
+			if len(locks) == 0 {
+				fmt.Println(success("✔ No state locks held"))
+				return
+			}
+			if time.Now().After(deadline) {
+				printLocksTable(locks, lockTTL)
+				log.Fatalf("%s Timed out waiting for locks to clear", fail("✖"))
+			}
+			time.Sleep(10 * time.Second)
+		}
+
+	default:
+		log.Fatalf("%s Unknown locks subcommand %q", fail("✖"), args[0])
+	}
+}
+
+// printLocksTable renders locks in a colorized table, highlighting locks
+// older than ttlMinutes in red so a stale lock left by a crashed deploy
+// stands out from an active, in-progress one.
+func printLocksTable(locks []backend.LockInfo, ttlMinutes int) {
+	header := color.New(color.FgCyan, color.Bold).SprintFunc()
+	staleRow := color.New(color.FgRed).SprintFunc()
+	activeRow := color.New(color.FgYellow).SprintFunc()
+
+	fmt.Println(header(fmt.Sprintf("%-36s %-12s %-20s %-25s %s", "ID", "OPERATION", "WHO", "CREATED", "PATH")))
+	for _, l := range locks {
+		row := fmt.Sprintf("%-36s %-12s %-20s %-25s %s", l.ID, l.Operation, l.Who, l.Created, l.Path)
+		if isStale(l, ttlMinutes) {
+			fmt.Println(staleRow(row))
+		} else {
+			fmt.Println(activeRow(row))
+		}
+	}
+}
+
+// isStale reports whether l was created more than ttlMinutes ago. A lock
+// with an unparseable Created timestamp is treated as not stale, since we
+// can't tell its age.
+func isStale(l backend.LockInfo, ttlMinutes int) bool {
+	created, err := time.Parse(time.RFC3339, l.Created)
+	if err != nil {
+		return false
+	}
+	return time.Since(created) > time.Duration(ttlMinutes)*time.Minute
+}
+
+// preflightLocks returns an error (rather than exiting the process) when an
+// unresolved stale lock, or a failure to even list locks, means the caller
+// should not proceed. It's called once before the DAG starts and again by
+// each worker goroutine right before its stack deploys, so a lock left
+// behind by a sibling stack that crashed mid-run is still caught once it
+// is older than ttlMinutes; since
+// several goroutines may call it concurrently, it must never call
+// log.Fatal/os.Exit itself, or one stack's stale lock would kill every
+// other stack's in-flight `cdktf deploy`.
+func preflightLocks(ctx context.Context, provider backend.Provider, ttlMinutes int, success, warn, fail func(a ...interface{}) string) error {
+	locks, err := provider.ListLocks(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list state locks: %w", err)
+	}
+	if len(locks) == 0 {
+		return nil
+	}
+
+	var stale []backend.LockInfo
+	for _, l := range locks {
+		if isStale(l, ttlMinutes) {
+			stale = append(stale, l)
+		}
+	}
+	if len(stale) == 0 {
+		fmt.Println(warn("==> Active state lock(s) detected, none older than", ttlMinutes, "minutes; continuing"))
+		return nil
+	}
+
+	fmt.Println(fail("==> Stale state lock(s) detected (older than", ttlMinutes, "minutes):"))
+	printLocksTable(stale, ttlMinutes)
+
+	if !stdinIsTTY() {
+		return fmt.Errorf("refusing to proceed with stale locks held; run `escambo-pipeline locks unlock <id>` first")
+	}
+
+	if !confirm("Continue anyway? [y/N]: ") {
+		return fmt.Errorf("aborting: stale locks were not cleared")
+	}
+	return nil
+}
+
+func stdinIsTTY() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// confirmMu serializes every interactive [y/N] prompt across concurrent DAG
+// workers. Without it, two stacks prompting at once (e.g. two prod stacks)
+// would interleave their prompt text and race on reads from the same stdin
+// fd, letting one stack's typed answer get consumed by another's prompt.
+var confirmMu sync.Mutex
+
+// confirm prints prompt and reads a y/N answer from stdin, holding
+// confirmMu for the full print-then-read so no other goroutine's prompt or
+// read can interleave with this one.
+func confirm(prompt string) bool {
+	confirmMu.Lock()
+	defer confirmMu.Unlock()
+	fmt.Print(prompt)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimSpace(strings.ToLower(answer)) == "y"
+}
@@ -4,18 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
-	ddbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
-	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/fatih/color"
+
+	"escambo-pipeline/backend"
 )
 
 type SharedConfig struct {
@@ -24,14 +24,54 @@ type SharedConfig struct {
 	} `json:"aws"`
 	BaseDomain string `json:"baseDomain"`
 	Terraform  struct {
-		Backend struct {
-			Bucket        string `json:"bucket"`
-			DynamoDBTable string `json:"dynamodbTable"`
-		} `json:"backend"`
+		Backend        backend.Config `json:"backend"`
+		LockTTLMinutes int            `json:"lockTtlMinutes"`
 	} `json:"terraform"`
 }
 
+// defaultLockTTLMinutes is how old a state lock must be before the
+// pipeline treats it as stale rather than an in-flight deploy.
+const defaultLockTTLMinutes = 15
+
 func main() {
+	ctx := context.Background()
+
+	approve := false
+	parallelism := runtime.NumCPU()
+	for i, arg := range os.Args[1:] {
+		switch {
+		case arg == "--approve":
+			approve = true
+		case strings.HasPrefix(arg, "--parallelism="):
+			if n, err := strconv.Atoi(strings.TrimPrefix(arg, "--parallelism=")); err == nil && n > 0 {
+				parallelism = n
+			}
+		case arg == "--parallelism" && i+2 < len(os.Args):
+			if n, err := strconv.Atoi(os.Args[i+2]); err == nil && n > 0 {
+				parallelism = n
+			}
+		}
+	}
+
+	// `escambo-pipeline locks list|unlock <id>|wait` bypasses the deploy
+	// loop entirely; it's a standalone preflight tool.
+	if len(os.Args) > 1 && os.Args[1] == "locks" {
+		sharedCfg, err := loadSharedConfig()
+		if err != nil {
+			log.Fatalf("Failed to read shared config: %v", err)
+		}
+		provider, err := backend.New(sharedCfg.Terraform.Backend)
+		if err != nil {
+			log.Fatalf("Failed to load backend provider: %v", err)
+		}
+		lockTTL := sharedCfg.Terraform.LockTTLMinutes
+		if lockTTL <= 0 {
+			lockTTL = defaultLockTTLMinutes
+		}
+		runLocksCommand(ctx, os.Args[2:], provider, lockTTL)
+		return
+	}
+
 	title := color.New(color.FgCyan, color.Bold).SprintFunc()
 	success := color.New(color.FgGreen).SprintFunc()
 	fail := color.New(color.FgRed).SprintFunc()
@@ -45,68 +85,60 @@ func main() {
 	setupCdktfDeps(success, fail)
 
 	// --- Load shared config ---
-	sharedFile := filepath.Join("config", "shared", "shared.json")
-	sharedData, err := os.ReadFile(sharedFile)
+	sharedCfg, err := loadSharedConfig()
 	if err != nil {
 		log.Fatalf("Failed to read shared config: %v", err)
 	}
-	var sharedCfg SharedConfig
-	if err := json.Unmarshal(sharedData, &sharedCfg); err != nil {
-		log.Fatalf("Invalid shared config JSON: %v", err)
+
+	lockTTL := sharedCfg.Terraform.LockTTLMinutes
+	if lockTTL <= 0 {
+		lockTTL = defaultLockTTLMinutes
 	}
 
 	// --- Backend setup ---
-	setupBackend(sharedCfg, success, fail, warn)
+	provider, err := backend.New(sharedCfg.Terraform.Backend)
+	if err != nil {
+		log.Fatalf("Failed to load backend provider: %v", err)
+	}
+	fmt.Println(warn("==> Ensuring Terraform state backend is provisioned"))
+	if err := provider.EnsureBackend(ctx); err != nil {
+		log.Fatalf("%s Failed to ensure backend: %v", fail("✖"), err)
+	}
+	fmt.Println(success("✔ Terraform state backend ready"))
 
-	// --- Deploy global stacks ---
-	if !deployStack("escambo-dns", success, fail) {
-		os.Exit(1)
-	} 
+	// --- Preflight: refuse to start with stale locks held ---
+	if err := preflightLocks(ctx, provider, lockTTL, success, warn, fail); err != nil {
+		log.Fatalf("%s %v", fail("✖"), err)
+	}
 
-	// --- Deploy per-environment stacks ---
+	// --- Collect environments ---
 	envDir := filepath.Join("config", "env")
 	envFiles, err := filepath.Glob(filepath.Join(envDir, "*.json"))
 	if err != nil {
 		log.Fatalf("Failed to list env configs: %v", err)
 	}
 
-	stacks := []string{
-		"ecr",
-		"customer-react-web-client",
-		"provider-react-web-client",
-		"core-java-service",
-		"widgets-java-service",
-	}
-
+	var envs []string
 	for _, file := range envFiles {
 		env := filepath.Base(file[:len(file)-len(filepath.Ext(file))])
 		if env == "qa" {
 			continue
 		}
+		envs = append(envs, env)
+	}
 
-		envData, err := os.ReadFile(file)
-		if err != nil {
-			log.Fatalf("Failed to read env config %s: %v", file, err)
-		}
-		var cfg map[string]interface{}
-		_ = json.Unmarshal(envData, &cfg)
-
-		fmt.Println(title("\n------------------------------------------------------------"))
-		fmt.Println("Environment:", color.YellowString(env))
-		fmt.Println("Config file:", file)
-		fmt.Println("Region:     ", sharedCfg.Aws.Region)
-		fmt.Println("Base Domain:", sharedCfg.BaseDomain)
-		fmt.Println("S3 Bucket:  ", sharedCfg.Terraform.Backend.Bucket)
-		fmt.Println("DynamoDB:   ", sharedCfg.Terraform.Backend.DynamoDBTable)
-
-		fmt.Println(title("------------------------------------------------------------"))
-
-		for _, suffix := range stacks {
-			stackName := "escambo-" + env + "-" + suffix
-			if !deployStack(stackName, success, fail) {
-				os.Exit(1)
-			}
-		}
+	fmt.Println(title("\n------------------------------------------------------------"))
+	fmt.Println("Environments:", color.YellowString(strings.Join(envs, ", ")))
+	fmt.Println("Region:     ", sharedCfg.Aws.Region)
+	fmt.Println("Base Domain:", sharedCfg.BaseDomain)
+	fmt.Println("Backend:    ", sharedCfg.Terraform.Backend.Type)
+	fmt.Println("Parallelism:", parallelism)
+	fmt.Println(title("------------------------------------------------------------"))
+
+	// --- Deploy stacks as a dependency DAG ---
+	defs := buildStackDefs(envs)
+	if !deployStacksDAG(ctx, defs, provider, approve, parallelism, lockTTL, success, warn, fail) {
+		os.Exit(1)
 	}
 
 	fmt.Println(success("\n============================================================"))
@@ -114,6 +146,23 @@ func main() {
 	fmt.Println(success("============================================================"))
 }
 
+// loadSharedConfig reads and parses config/shared/shared.json, filling in
+// the backend region from the top-level aws config since shared.json
+// declares region once rather than per-backend.
+func loadSharedConfig() (SharedConfig, error) {
+	var cfg SharedConfig
+	sharedFile := filepath.Join("config", "shared", "shared.json")
+	data, err := os.ReadFile(sharedFile)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, err
+	}
+	cfg.Terraform.Backend.Region = cfg.Aws.Region
+	return cfg, nil
+}
+
 // --- Install npm ci + cdktf get ---
 func setupCdktfDeps(success, fail func(a ...interface{}) string) {
 	fmt.Println(color.YellowString("==> Installing Node.js dependencies (npm ci)"))
@@ -143,87 +192,72 @@ func setupCdktfDeps(success, fail func(a ...interface{}) string) {
 	fmt.Println(success("✔ cdktf synth completed"))
 }
 
-// --- Backend setup (S3 + DynamoDB) ---
-func setupBackend(cfg SharedConfig, success, fail, warn func(a ...interface{}) string) {
-	ctx := context.Background()
-	region := cfg.Aws.Region
-	bucket := cfg.Terraform.Backend.Bucket
-	table := cfg.Terraform.Backend.DynamoDBTable
+// writeBackendConfig renders the stack's backend block through the active
+// Provider and writes it alongside CDKTF's own generated Terraform JSON, so
+// `cdktf deploy` shells out to a `terraform` that sees both files. It
+// returns an error rather than exiting the process, since it's called from
+// inside a DAG worker goroutine where a single stack's failure must not
+// kill sibling stacks that are already deploying.
+func writeBackendConfig(provider backend.Provider, stack string) error {
+	cfg, err := provider.RenderCDKTFBackendConfig(stack)
+	if err != nil {
+		return fmt.Errorf("failed to render backend config for %s: %w", stack, err)
+	}
 
-	awsCfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	data, err := json.MarshalIndent(map[string]any{"terraform": map[string]any{"backend": cfg}}, "", "  ")
 	if err != nil {
-		log.Fatalf("Unable to load AWS SDK config: %v", err)
-	}
-
-	s3Client := s3.NewFromConfig(awsCfg)
-	dynamo := dynamodb.NewFromConfig(awsCfg)
-
-	fmt.Println(warn("==> Checking S3 bucket:", bucket))
-	_, err = s3Client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucket)})
-	if err == nil {
-		fmt.Println(success("✔ Bucket already exists:", bucket))
-	} else {
-		fmt.Println(warn("Creating bucket:", bucket))
-		if region == "us-east-1" {
-			_, err = s3Client.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: aws.String(bucket)})
-		} else {
-			_, err = s3Client.CreateBucket(ctx, &s3.CreateBucketInput{
-				Bucket: aws.String(bucket),
-				CreateBucketConfiguration: &s3Types.CreateBucketConfiguration{
-					LocationConstraint: s3Types.BucketLocationConstraint(region),
-				},
-			})
-		}
-		if err != nil {
-			log.Fatalf("%s Failed to create bucket: %v", fail("✖"), err)
-		}
-		fmt.Println(success("✔ Bucket created:", bucket))
+		return fmt.Errorf("failed to marshal backend config for %s: %w", stack, err)
 	}
 
-	_, err = s3Client.PutBucketVersioning(ctx, &s3.PutBucketVersioningInput{
-		Bucket: aws.String(bucket),
-		VersioningConfiguration: &s3Types.VersioningConfiguration{
-			Status: s3Types.BucketVersioningStatusEnabled,
-		},
-	})
+	stackDir := filepath.Join("cdktf.out", "stacks", stack)
+	if err := os.MkdirAll(stackDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create stack output dir for %s: %w", stack, err)
+	}
+	if err := os.WriteFile(filepath.Join(stackDir, "backend.tf.json"), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write backend config for %s: %w", stack, err)
+	}
+	return nil
+}
+
+// deployStack plans the stack with `cdktf diff`, skips stacks with no
+// changes, auto-applies plans that neither destroy resources nor target
+// prod, and otherwise requires --approve or a TTY confirmation before
+// running `cdktf deploy --auto-approve`. All status lines and the
+// deploy's own stdout/stderr go through logw, so concurrent workers each
+// write through their own prefixed, mutex-serialized writer.
+func deployStack(name, env string, approve bool, logw io.Writer, success, warn, fail func(a ...interface{}) string) bool {
+	fmt.Fprintln(logw, color.YellowString("==> Planning stack: %s", name))
+	plan, err := planStack(name)
 	if err != nil {
-		log.Fatalf("%s Failed to enable versioning: %v", fail("✖"), err)
-	}
-	fmt.Println(success("✔ Versioning enabled on bucket:", bucket))
-
-	fmt.Println(warn("==> Checking DynamoDB table:", table))
-	_, err = dynamo.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
-	if err == nil {
-		fmt.Println(success("✔ Table already exists:", table))
-	} else {
-		fmt.Println(warn("Creating DynamoDB table:", table))
-		_, err = dynamo.CreateTable(ctx, &dynamodb.CreateTableInput{
-			TableName: aws.String(table),
-			AttributeDefinitions: []ddbTypes.AttributeDefinition{
-				{AttributeName: aws.String("LockID"), AttributeType: ddbTypes.ScalarAttributeTypeS},
-			},
-			KeySchema: []ddbTypes.KeySchemaElement{
-				{AttributeName: aws.String("LockID"), KeyType: ddbTypes.KeyTypeHash},
-			},
-			BillingMode: ddbTypes.BillingModePayPerRequest,
-		})
-		if err != nil {
-			log.Fatalf("%s Failed to create table: %v", fail("✖"), err)
+		fmt.Fprintln(logw, fail("[FAILED] "+name+" plan failed: "+err.Error()))
+		return false
+	}
+
+	if plan.Empty() {
+		fmt.Fprintln(logw, success("[SKIP] "+name+" has no changes"))
+		return true
+	}
+	fmt.Fprintln(logw, warn(fmt.Sprintf("Plan for %s: %d to add, %d to change, %d to destroy", name, plan.Adds, plan.Changes, plan.Destroys)))
+
+	if gated := plan.Destroys > 0 || env == "prod"; gated && !approve {
+		if !stdinIsTTY() {
+			fmt.Fprintln(logw, fail(fmt.Sprintf("[FAILED] %s requires --approve or a TTY confirmation (destroys=%d, env=%s)", name, plan.Destroys, env)))
+			return false
+		}
+		if !confirm(fmt.Sprintf("Apply %s? [y/N]: ", name)) {
+			fmt.Fprintln(logw, fail("[ABORTED] "+name+" apply declined"))
+			return false
 		}
-		fmt.Println(success("✔ Table created:", table))
 	}
-}
 
-// --- Deploy stack using `cdktf deploy` ---
-func deployStack(name string, success, fail func(a ...interface{}) string) bool {
-	fmt.Println(color.YellowString("==> Deploying stack: %s", name))
+	fmt.Fprintln(logw, color.YellowString("==> Applying stack: %s", name))
 	cmd := exec.Command("npx", "cdktf", "deploy", name, "--auto-approve")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stdout = logw
+	cmd.Stderr = logw
 	if err := cmd.Run(); err != nil {
-		fmt.Println(fail("[FAILED] " + name + " deployment failed!"))
+		fmt.Fprintln(logw, fail("[FAILED] "+name+" deployment failed!"))
 		return false
 	}
-	fmt.Println(success("[DONE] " + name + " deployed successfully"))
+	fmt.Fprintln(logw, success("[DONE] "+name+" deployed successfully"))
 	return true
 }
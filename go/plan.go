@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+// PlanSummary is the resource-change count `cdktf diff` reports for a
+// stack, mirroring the "Plan: X to add, Y to change, Z to destroy" line
+// it prints for humans.
+type PlanSummary struct {
+	Adds     int
+	Changes  int
+	Destroys int
+}
+
+// Empty reports whether the plan has no changes to apply.
+func (p PlanSummary) Empty() bool {
+	return p.Adds == 0 && p.Changes == 0 && p.Destroys == 0
+}
+
+// planStack runs `cdktf diff <name> --output-json` and parses the
+// resource-change summary out of its JSON output.
+func planStack(name string) (PlanSummary, error) {
+	cmd := exec.Command("npx", "cdktf", "diff", name, "--output-json")
+	out, err := cmd.Output()
+	if err != nil {
+		return PlanSummary{}, fmt.Errorf("cdktf diff failed: %w", err)
+	}
+
+	var report struct {
+		Summary struct {
+			Add     int `json:"add"`
+			Change  int `json:"change"`
+			Destroy int `json:"destroy"`
+		} `json:"summary"`
+	}
+	if err := json.Unmarshal(out, &report); err != nil {
+		return PlanSummary{}, fmt.Errorf("failed to parse diff output: %w", err)
+	}
+
+	return PlanSummary{
+		Adds:     report.Summary.Add,
+		Changes:  report.Summary.Change,
+		Destroys: report.Summary.Destroy,
+	}, nil
+}